@@ -0,0 +1,117 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/cilium/pkg/command"
+	"github.com/cilium/cilium/pkg/common"
+	"github.com/cilium/cilium/pkg/maps/neighborsmap"
+)
+
+// bpfNodeportNeighCmd represents the bpf_nodeport_neigh command
+var bpfNodeportNeighCmd = &cobra.Command{
+	Use:   "nodeport-neigh",
+	Short: "Manage the node port L2 neighbor table",
+}
+
+var bpfNodeportNeighListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the contents of the node port neighbor table",
+	Run: func(cmd *cobra.Command, args []string) {
+		common.RequireRootPrivilege("cilium bpf nodeport-neigh list")
+
+		entries, err := neighborsmap.DumpToSlice()
+		if err != nil {
+			Fatalf("error dumping contents of the node port neighbor maps: %s", err)
+		}
+
+		if command.OutputOption() {
+			if err := command.PrintOutput(entries); err != nil {
+				Fatalf("error getting output of the node port neighbor maps: %s", err)
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 5, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "IP\tMAC\tIFINDEX\tVLAN\tNEEDS-REFRESH")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%t\n", e.IP, e.MAC, e.Ifindex, e.Vlan, e.NeedsRefresh)
+		}
+		w.Flush()
+	},
+}
+
+var bpfNodeportNeighGetCmd = &cobra.Command{
+	Use:     "get <ip>",
+	Aliases: []string{"lookup"},
+	Short:   "Look up the MAC address cached for an IP in the node port neighbor table",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		common.RequireRootPrivilege("cilium bpf nodeport-neigh get")
+
+		ip := net.ParseIP(args[0])
+		if ip == nil {
+			Fatalf("invalid IP address: %s", args[0])
+		}
+
+		mac, err := neighborsmap.Lookup(ip)
+		if err != nil {
+			Fatalf("error looking up %s in the node port neighbor maps: %s", ip, err)
+		}
+
+		if command.OutputOption() {
+			if err := command.PrintOutput(mac); err != nil {
+				Fatalf("error getting output of the node port neighbor maps: %s", err)
+			}
+			return
+		}
+
+		fmt.Println(mac)
+	},
+}
+
+var bpfNodeportNeighDeleteCmd = &cobra.Command{
+	Use:     "delete <ip>",
+	Aliases: []string{"del"},
+	Short:   "Delete an entry from the node port neighbor table",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		common.RequireRootPrivilege("cilium bpf nodeport-neigh delete")
+
+		ip := net.ParseIP(args[0])
+		if ip == nil {
+			Fatalf("invalid IP address: %s", args[0])
+		}
+
+		neighborsmap.NeighRetire(ip)
+	},
+}
+
+func init() {
+	bpfNodeportNeighCmd.AddCommand(bpfNodeportNeighListCmd)
+	bpfNodeportNeighCmd.AddCommand(bpfNodeportNeighGetCmd)
+	bpfNodeportNeighCmd.AddCommand(bpfNodeportNeighDeleteCmd)
+	bpfCmd.AddCommand(bpfNodeportNeighCmd)
+	command.AddOutputOption(bpfNodeportNeighListCmd)
+	command.AddOutputOption(bpfNodeportNeighGetCmd)
+}
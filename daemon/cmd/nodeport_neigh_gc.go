@@ -0,0 +1,37 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/cilium/cilium/pkg/maps/neighborsmap"
+	"github.com/cilium/cilium/pkg/option"
+)
+
+func init() {
+	flags := rootCmd.Flags()
+	flags.Duration(option.NodePortNeighMaxAge, 30*time.Minute,
+		"Max age of nodeport neighbor entries before the periodic GC retires them (0 disables the GC)")
+	option.BindEnv(option.NodePortNeighMaxAge)
+}
+
+// startNodePortNeighGC kicks off the periodic nodeport neighbor GC as a
+// background goroutine, the same way the CT and NAT GC are started from
+// the daemon's init sequence.
+func startNodePortNeighGC(ctx context.Context) {
+	go neighborsmap.RunGC(ctx, option.Config.NodePortNeighMaxAge)
+}
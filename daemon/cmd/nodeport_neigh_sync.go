@@ -0,0 +1,37 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/cilium/cilium/pkg/datapath/neighbor"
+)
+
+// startNodePortNeighSyncer starts mirroring the kernel's ARP/NDP neighbor
+// table into the nodeport neighbor maps for the lifetime of the daemon. A
+// nil filter tracks every kernel neighbor update; this will be narrowed
+// to the LB backend table once that lookup is wired in.
+func startNodePortNeighSyncer(ctx context.Context) {
+	syncer := neighbor.NewSyncer(nil)
+	if err := syncer.Start(ctx); err != nil {
+		log.WithError(err).Warning("unable to start nodeport neighbor netlink syncer")
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		syncer.Stop()
+	}()
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd implements the cilium-agent command-line entry point and
+// its startup sequence.
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/cilium/pkg/logging"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+var log = logging.DefaultLogger.WithField(logfields.LogSubsys, "daemon")
+
+// rootCmd is the base command for the cilium-agent daemon.
+var rootCmd = &cobra.Command{
+	Use:   "cilium-agent",
+	Short: "Run the cilium agent",
+	Run: func(cmd *cobra.Command, args []string) {
+		runDaemon()
+	},
+}
+
+// Execute runs the daemon's root command, whose run handler sets up signal
+// handling. It is called by cilium-agent's main().
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		log.WithError(err).Fatal("error running cilium-agent")
+		os.Exit(1)
+	}
+}
+
+// runDaemon brings up the agent's long-running background jobs. It
+// blocks until the process receives a shutdown signal.
+func runDaemon() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	startNodePortNeighGC(ctx)
+	startNodePortNeighSyncer(ctx)
+
+	<-ctx.Done()
+}
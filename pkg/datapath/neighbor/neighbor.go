@@ -0,0 +1,141 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package neighbor mirrors the kernel's ARP/NDP neighbor table into the
+// nodeport neighbor BPF maps so the datapath can rely on the kernel's own
+// neighbor discovery instead of learning L2 mappings lazily off the first
+// packet of a flow.
+package neighbor
+
+import (
+	"context"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/cilium/cilium/pkg/logging"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/maps/neighborsmap"
+)
+
+var log = logging.DefaultLogger.WithField(logfields.LogSubsys, "datapath-neighbor")
+
+// FilterFunc reports whether ip belongs to a backend the nodeport
+// datapath cares about. Kernel neighbor updates for IPs it rejects are
+// ignored.
+type FilterFunc func(ip net.IP) bool
+
+// Syncer subscribes to kernel neighbor table events and mirrors
+// NUD_REACHABLE / NUD_STALE transitions for backend IPs into the
+// cilium_nodeport_neigh4/6 maps, retiring entries the kernel deletes or
+// marks as failed.
+type Syncer struct {
+	filter FilterFunc
+	cancel context.CancelFunc
+}
+
+// NewSyncer creates a Syncer that only tracks neighbor updates for IPs
+// accepted by filter. A nil filter tracks every update.
+func NewSyncer(filter FilterFunc) *Syncer {
+	return &Syncer{filter: filter}
+}
+
+// Start subscribes to RTM_NEWNEIGH/RTM_DELNEIGH netlink multicast events
+// and mirrors them into the nodeport neighbor maps until ctx is cancelled
+// or Stop is called.
+func (s *Syncer) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	updates := make(chan netlink.NeighUpdate)
+	done := make(chan struct{})
+	if err := netlink.NeighSubscribe(updates, done); err != nil {
+		cancel()
+		return err
+	}
+
+	s.cancel = func() {
+		cancel()
+		close(done)
+	}
+
+	go s.run(ctx, updates)
+	return nil
+}
+
+// Stop terminates the netlink subscription started by Start.
+func (s *Syncer) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+func (s *Syncer) run(ctx context.Context, updates chan netlink.NeighUpdate) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-updates:
+			if !ok {
+				return
+			}
+			s.handle(u)
+		}
+	}
+}
+
+// action is the outcome decideAction reaches for a netlink neighbor
+// update: whether it should be mirrored into the nodeport neighbor maps,
+// retired from them, or ignored.
+type action int
+
+const (
+	actionIgnore action = iota
+	actionRetire
+	actionUpdate
+)
+
+// decideAction inspects a netlink neighbor update and reports what the
+// nodeport neighbor maps should do in response. It is pure so the NUD
+// state machine can be unit tested without a real netlink socket.
+func decideAction(u netlink.NeighUpdate) action {
+	switch {
+	case u.Type == unix.RTM_DELNEIGH, u.State&(netlink.NUD_FAILED|netlink.NUD_INCOMPLETE) != 0:
+		return actionRetire
+	case u.State&(netlink.NUD_REACHABLE|netlink.NUD_STALE) != 0:
+		if len(u.HardwareAddr) == 0 {
+			return actionIgnore
+		}
+		return actionUpdate
+	default:
+		return actionIgnore
+	}
+}
+
+func (s *Syncer) handle(u netlink.NeighUpdate) {
+	ip := u.IP
+	if ip == nil || (s.filter != nil && !s.filter(ip)) {
+		return
+	}
+
+	switch decideAction(u) {
+	case actionRetire:
+		neighborsmap.NeighRetire(ip)
+	case actionUpdate:
+		if err := neighborsmap.Update(ip, u.HardwareAddr, uint32(u.LinkIndex), uint16(u.Vlan)); err != nil {
+			log.WithError(err).WithField(logfields.IPAddr, ip.String()).
+				Warning("unable to mirror kernel neighbor entry into nodeport neighbor map")
+		}
+	}
+}
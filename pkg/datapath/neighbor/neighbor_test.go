@@ -0,0 +1,77 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package neighbor
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestDecideAction(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+
+	tests := []struct {
+		name string
+		u    netlink.NeighUpdate
+		want action
+	}{
+		{
+			name: "kernel deletes the entry",
+			u:    netlink.NeighUpdate{Type: unix.RTM_DELNEIGH},
+			want: actionRetire,
+		},
+		{
+			name: "entry goes failed",
+			u:    netlink.NeighUpdate{Type: unix.RTM_NEWNEIGH, Neigh: netlink.Neigh{State: netlink.NUD_FAILED}},
+			want: actionRetire,
+		},
+		{
+			name: "entry goes incomplete",
+			u:    netlink.NeighUpdate{Type: unix.RTM_NEWNEIGH, Neigh: netlink.Neigh{State: netlink.NUD_INCOMPLETE}},
+			want: actionRetire,
+		},
+		{
+			name: "entry becomes reachable with a MAC",
+			u:    netlink.NeighUpdate{Type: unix.RTM_NEWNEIGH, Neigh: netlink.Neigh{State: netlink.NUD_REACHABLE, HardwareAddr: mac}},
+			want: actionUpdate,
+		},
+		{
+			name: "entry goes stale with a MAC",
+			u:    netlink.NeighUpdate{Type: unix.RTM_NEWNEIGH, Neigh: netlink.Neigh{State: netlink.NUD_STALE, HardwareAddr: mac}},
+			want: actionUpdate,
+		},
+		{
+			name: "reachable without a MAC is ignored",
+			u:    netlink.NeighUpdate{Type: unix.RTM_NEWNEIGH, Neigh: netlink.Neigh{State: netlink.NUD_REACHABLE}},
+			want: actionIgnore,
+		},
+		{
+			name: "uninteresting state is ignored",
+			u:    netlink.NeighUpdate{Type: unix.RTM_NEWNEIGH, Neigh: netlink.Neigh{State: netlink.NUD_NONE}},
+			want: actionIgnore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decideAction(tt.u); got != tt.want {
+				t.Errorf("decideAction(%+v) = %v, want %v", tt.u, got, tt.want)
+			}
+		})
+	}
+}
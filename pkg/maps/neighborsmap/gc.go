@@ -0,0 +1,129 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package neighborsmap
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/cilium/cilium/pkg/bpf"
+	"github.com/cilium/cilium/pkg/logging"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+// defaultGCInterval is the period between successive GC runs over the
+// nodeport neighbor maps.
+const defaultGCInterval = 30 * time.Second
+
+var log = logging.DefaultLogger.WithField(logfields.LogSubsys, "map-neighbors")
+
+// toDeltaSecs returns the number of whole seconds elapsed since the
+// monotonic timestamp created, as recorded by ktime_get_ns() in the
+// datapath.
+func toDeltaSecs(created uint64) uint32 {
+	return deltaSecs(created, uint64(bpf.GetMtime()))
+}
+
+// deltaSecs is the pure arithmetic behind toDeltaSecs, split out so it can
+// be unit tested without a real ktime source.
+func deltaSecs(created, now uint64) uint32 {
+	if now <= created {
+		return 0
+	}
+	return uint32((now - created) / uint64(time.Second))
+}
+
+// RunGC periodically walks the nodeport neighbor maps and retires entries
+// whose Created timestamp is older than maxAge. It is meant to be kicked
+// off as a goroutine by the daemon, alongside the CT and NAT GC, and runs
+// until ctx is cancelled. maxAge <= 0 disables the GC.
+func RunGC(ctx context.Context, maxAge time.Duration) {
+	ticker := time.NewTicker(defaultGCInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := gc(maxAge); err != nil {
+				log.WithError(err).Warning("error garbage collecting nodeport neighbor entries")
+			}
+		}
+	}
+}
+
+// gcVerdict is the decision gc reaches for a single entry: leave it alone,
+// mark it as needing a refresh, or retire it outright.
+type gcVerdict int
+
+const (
+	gcVerdictKeep gcVerdict = iota
+	gcVerdictNeedsRefresh
+	gcVerdictRetire
+)
+
+// classifyAge decides what gc should do with an entry of the given age,
+// given maxAgeSecs. An entry at or past maxAgeSecs is retired; one at or
+// past half of maxAgeSecs is marked as needing a refresh so the datapath synchronizer
+// can resolve a fresh mapping before it goes stale. maxAgeSecs == 0 means
+// the GC is disabled and every entry is kept.
+func classifyAge(ageSecs, maxAgeSecs uint32) gcVerdict {
+	if maxAgeSecs == 0 {
+		return gcVerdictKeep
+	}
+	if ageSecs >= maxAgeSecs {
+		return gcVerdictRetire
+	}
+	if ageSecs >= maxAgeSecs/2 {
+		return gcVerdictNeedsRefresh
+	}
+	return gcVerdictKeep
+}
+
+// gc retires every entry at least maxAge old and marks entries at least
+// half of maxAge old as needing a refresh. maxAge <= 0 is a no-op: it must not be
+// treated as "age zero" or every entry would be retired on the next tick.
+func gc(maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	maxAgeSecs := uint32(maxAge / time.Second)
+
+	var stale, needsRefresh []net.IP
+	err := DumpWithCallback(func(e Entry) {
+		switch classifyAge(toDeltaSecs(e.Created), maxAgeSecs) {
+		case gcVerdictRetire:
+			stale = append(stale, e.IP)
+		case gcVerdictNeedsRefresh:
+			if !e.NeedsRefresh {
+				needsRefresh = append(needsRefresh, e.IP)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range stale {
+		NeighRetire(ip)
+	}
+	for _, ip := range needsRefresh {
+		if err := SetNeedsRefresh(ip); err != nil {
+			log.WithError(err).Warning("error marking nodeport neighbor entry as needing a refresh")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package neighborsmap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeltaSecs(t *testing.T) {
+	tests := []struct {
+		name    string
+		created uint64
+		now     uint64
+		want    uint32
+	}{
+		{"zero age", 100, 100, 0},
+		{"now before created", 100, 50, 0},
+		{"ten seconds", 0, uint64(10 * time.Second), 10},
+		{"sub-second rounds down", 0, uint64(1500 * time.Millisecond), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deltaSecs(tt.created, tt.now); got != tt.want {
+				t.Errorf("deltaSecs(%d, %d) = %d, want %d", tt.created, tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyAge(t *testing.T) {
+	tests := []struct {
+		name       string
+		ageSecs    uint32
+		maxAgeSecs uint32
+		want       gcVerdict
+	}{
+		// maxAgeSecs == 0 means the GC is disabled: every entry must be
+		// kept, including a freshly-created one with ageSecs == 0. This is
+		// the regression this test guards: age >= maxAgeSecs used to be
+		// true for every entry once maxAgeSecs was 0.
+		{"disabled GC keeps a fresh entry", 0, 0, gcVerdictKeep},
+		{"disabled GC keeps an old entry", 1_000_000, 0, gcVerdictKeep},
+		{"fresh entry is kept", 1, 100, gcVerdictKeep},
+		{"entry at half of max age needs a refresh", 50, 100, gcVerdictNeedsRefresh},
+		{"entry at max age is retired", 100, 100, gcVerdictRetire},
+		{"entry past max age is retired", 200, 100, gcVerdictRetire},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyAge(tt.ageSecs, tt.maxAgeSecs); got != tt.want {
+				t.Errorf("classifyAge(%d, %d) = %v, want %v", tt.ageSecs, tt.maxAgeSecs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGCNoopOnZeroMaxAge(t *testing.T) {
+	if err := gc(0); err != nil {
+		t.Fatalf("gc(0) returned error %v, expected it to be a no-op", err)
+	}
+	if err := gc(-time.Second); err != nil {
+		t.Fatalf("gc(-1s) returned error %v, expected it to be a no-op", err)
+	}
+}
@@ -85,6 +85,26 @@ type Key6 struct {
 type Value struct {
 	macaddr types.MACAddr
 	pad     uint16
+	// Created is the monotonic timestamp (as returned by ktime_get_ns() in
+	// the datapath) at which this entry was inserted or last refreshed. It
+	// mirrors NatEntry6.Created in pkg/maps/nat and lets the GC age out
+	// stale entries independently of LRU pressure.
+	Created uint64
+	// Ifindex is the network device the entry was learned on. It lets
+	// NeighRetireByIfindex flush the entries for a single link (e.g. on
+	// link-down) without wiping mappings reachable via other uplinks.
+	Ifindex uint32
+	// Vlan is the 802.1Q VLAN tag associated with Ifindex, or zero if the
+	// link is untagged.
+	Vlan uint16
+	// NeedsRefresh is set by the GC via SetNeedsRefresh once an entry
+	// reaches half of the GC's max age, surfaced through Entry.NeedsRefresh so a
+	// synchronizer (e.g. pkg/datapath/neighbor) can eagerly resolve a
+	// fresh mapping before the GC retires it for being fully stale. It is
+	// implicitly cleared by the next Update, since that writes a fresh
+	// zero-valued Value.
+	NeedsRefresh uint8
+	pad2         uint8
 }
 
 // GetKeyPtr returns the unsafe pointer to the BPF key
@@ -131,23 +151,164 @@ func InitMaps(ipv4, ipv6 bool) error {
 
 // NeighRetire retires a cached neigh entry from the LRU cache
 func NeighRetire(ip net.IP) {
-	var neighMap *bpf.Map
-	if len(ip) == net.IPv4len {
-		neighMap, _ = neighMapsGet()
-	} else {
-		_, neighMap = neighMapsGet()
-	}
+	neighMap, key := mapAndKey(ip)
 	if err := neighMap.Open(); err != nil {
 		return
 	}
 	defer neighMap.Close()
-	if len(ip) == net.IPv4len {
+	neighMap.Delete(key)
+}
+
+// matchIfindex reports whether e was learned on ifindex. It is split out
+// of NeighRetireByIfindex so the filtering logic can be unit tested
+// without a real BPF map.
+func matchIfindex(e Entry, ifindex uint32) bool {
+	return e.Ifindex == ifindex
+}
+
+// NeighRetireByIfindex retires every cached neigh entry learned on
+// ifindex, leaving entries learned on other links untouched. Each IP has
+// a single entry, recording only the link it was last learned on. It is meant to be called on link-down events for a specific
+// uplink, as a scoped alternative to wiping the whole cache.
+func NeighRetireByIfindex(ifindex uint32) error {
+	// Collect matches first and retire them once the dump has finished:
+	// NeighRetire opens and closes the same *bpf.Map handle that
+	// DumpWithCallback is iterating over, so calling it from inside the
+	// callback would close the map mid-dump (the same hazard gc() avoids
+	// by collecting stale IPs before calling NeighRetire).
+	var matches []net.IP
+	err := DumpWithCallback(func(e Entry) {
+		if matchIfindex(e, ifindex) {
+			matches = append(matches, e.IP)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, ip := range matches {
+		NeighRetire(ip)
+	}
+	return nil
+}
+
+// Entry is a single IP-to-MAC mapping as returned by DumpToSlice and
+// DumpWithCallback.
+type Entry struct {
+	IP           net.IP
+	MAC          net.HardwareAddr
+	Created      uint64
+	Ifindex      uint32
+	Vlan         uint16
+	NeedsRefresh bool
+}
+
+// String converts the entry into a human readable string format.
+func (e Entry) String() string { return e.IP.String() + " " + e.MAC.String() }
+
+// DumpWithCallback iterates over both the IPv4 and IPv6 nodeport neighbor
+// maps and calls cb on each entry found.
+func DumpWithCallback(cb func(Entry)) error {
+	neigh4Map, neigh6Map := neighMapsGet()
+	if err := neigh4Map.Open(); err != nil {
+		return err
+	}
+	defer neigh4Map.Close()
+	parse4 := func(key bpf.MapKey, value bpf.MapValue) {
+		k := key.(*Key4)
+		v := value.(*Value)
+		cb(Entry{IP: net.IP(k.ipv4[:]).To4(), MAC: net.HardwareAddr(v.macaddr[:]), Created: v.Created, Ifindex: v.Ifindex, Vlan: v.Vlan, NeedsRefresh: v.NeedsRefresh != 0})
+	}
+	if err := neigh4Map.DumpWithCallback(parse4); err != nil {
+		return err
+	}
+
+	if err := neigh6Map.Open(); err != nil {
+		return err
+	}
+	defer neigh6Map.Close()
+	parse6 := func(key bpf.MapKey, value bpf.MapValue) {
+		k := key.(*Key6)
+		v := value.(*Value)
+		cb(Entry{IP: net.IP(k.ipv6[:]), MAC: net.HardwareAddr(v.macaddr[:]), Created: v.Created, Ifindex: v.Ifindex, Vlan: v.Vlan, NeedsRefresh: v.NeedsRefresh != 0})
+	}
+	return neigh6Map.DumpWithCallback(parse6)
+}
+
+// DumpToSlice returns the contents of both nodeport neighbor maps as a slice
+// of Entry.
+func DumpToSlice() ([]Entry, error) {
+	entries := []Entry{}
+	err := DumpWithCallback(func(e Entry) {
+		entries = append(entries, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// mapAndKey returns the BPF map and key to use for ip, picking the IPv4 or
+// IPv6 map depending on whether ip holds an IPv4 address. net.ParseIP and
+// most other net.IP constructors always return a 16-byte slice, even for
+// dotted-decimal input, so the IPv4/IPv6 decision must be made with To4()
+// rather than by comparing len(ip) against net.IPv4len.
+func mapAndKey(ip net.IP) (*bpf.Map, bpf.MapKey) {
+	if ip4 := ip.To4(); ip4 != nil {
+		neighMap, _ := neighMapsGet()
 		key := &Key4{}
-		copy(key.ipv4[:], ip.To4())
-		neighMap.Delete(key)
-	} else {
-		key := &Key6{}
-		copy(key.ipv6[:], ip.To16())
-		neighMap.Delete(key)
+		copy(key.ipv4[:], ip4)
+		return neighMap, key
+	}
+	_, neighMap := neighMapsGet()
+	key := &Key6{}
+	copy(key.ipv6[:], ip.To16())
+	return neighMap, key
+}
+
+// Update inserts or updates the IP-to-MAC mapping for ip in the nodeport
+// neighbor cache, recording the device and VLAN it was learned on so a
+// later link-down can retire it via NeighRetireByIfindex.
+func Update(ip net.IP, mac net.HardwareAddr, ifindex uint32, vlan uint16) error {
+	neighMap, key := mapAndKey(ip)
+	if err := neighMap.Open(); err != nil {
+		return err
+	}
+	defer neighMap.Close()
+	value := &Value{Created: uint64(bpf.GetMtime()), Ifindex: ifindex, Vlan: vlan}
+	copy(value.macaddr[:], mac)
+	return neighMap.Update(key, value)
+}
+
+// SetNeedsRefresh marks the cached entry for ip as needing a refresh,
+// leaving every other field untouched. It is used by the GC to flag
+// entries approaching the GC's max age.
+func SetNeedsRefresh(ip net.IP) error {
+	neighMap, key := mapAndKey(ip)
+	if err := neighMap.Open(); err != nil {
+		return err
+	}
+	defer neighMap.Close()
+	v, err := neighMap.Lookup(key)
+	if err != nil {
+		return err
+	}
+	value := v.(*Value)
+	value.NeedsRefresh = 1
+	return neighMap.Update(key, value)
+}
+
+// Lookup returns the MAC address cached for ip, or an error if no entry
+// exists.
+func Lookup(ip net.IP) (net.HardwareAddr, error) {
+	neighMap, key := mapAndKey(ip)
+	if err := neighMap.Open(); err != nil {
+		return nil, err
+	}
+	defer neighMap.Close()
+	v, err := neighMap.Lookup(key)
+	if err != nil {
+		return nil, err
 	}
+	value := v.(*Value)
+	return net.HardwareAddr(value.macaddr[:]), nil
 }
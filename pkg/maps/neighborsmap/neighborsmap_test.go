@@ -0,0 +1,88 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package neighborsmap
+
+import (
+	"net"
+	"testing"
+)
+
+// TestMapAndKeyIPv4 guards against a regression where net.ParseIP's 4-in-6
+// encoding (always 16 bytes, even for dotted-decimal input) caused get/
+// delete for IPv4 addresses to be routed to the IPv6 map instead.
+func TestMapAndKeyIPv4(t *testing.T) {
+	for _, s := range []string{"192.168.1.1", "10.0.0.1", "0.0.0.0"} {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			t.Fatalf("net.ParseIP(%q) returned nil", s)
+		}
+		if got := len(ip); got != net.IPv6len {
+			t.Fatalf("net.ParseIP(%q) returned a %d-byte IP, expected %d (test assumption broken)", s, got, net.IPv6len)
+		}
+
+		neighMap, key := mapAndKey(ip)
+		if _, ok := key.(*Key4); !ok {
+			t.Errorf("mapAndKey(%q) returned key of type %T, expected *Key4", s, key)
+		}
+		if got, want := neighMap.Name(), Map4Name; got != want {
+			t.Errorf("mapAndKey(%q) returned map %q, expected %q", s, got, want)
+		}
+	}
+}
+
+func TestMapAndKeyIPv6(t *testing.T) {
+	for _, s := range []string{"fd00::1", "::1"} {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			t.Fatalf("net.ParseIP(%q) returned nil", s)
+		}
+
+		neighMap, key := mapAndKey(ip)
+		if _, ok := key.(*Key6); !ok {
+			t.Errorf("mapAndKey(%q) returned key of type %T, expected *Key6", s, key)
+		}
+		if got, want := neighMap.Name(), Map6Name; got != want {
+			t.Errorf("mapAndKey(%q) returned map %q, expected %q", s, got, want)
+		}
+	}
+}
+
+func TestMatchIfindex(t *testing.T) {
+	entries := []Entry{
+		{IP: net.ParseIP("192.168.1.1"), Ifindex: 2},
+		{IP: net.ParseIP("192.168.1.2"), Ifindex: 3},
+		{IP: net.ParseIP("fd00::1"), Ifindex: 2},
+	}
+
+	var matched []net.IP
+	for _, e := range entries {
+		if matchIfindex(e, 2) {
+			matched = append(matched, e.IP)
+		}
+	}
+
+	if len(matched) != 2 {
+		t.Fatalf("matchIfindex selected %d entries, expected 2: %v", len(matched), matched)
+	}
+	if !matched[0].Equal(entries[0].IP) || !matched[1].Equal(entries[2].IP) {
+		t.Errorf("matchIfindex selected %v, expected entries[0] and entries[2]", matched)
+	}
+
+	for _, e := range entries {
+		if matchIfindex(e, 99) {
+			t.Errorf("matchIfindex(%v, 99) = true, expected false", e)
+		}
+	}
+}